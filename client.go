@@ -0,0 +1,48 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package qmgo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Client wraps the underlying mongo.Client.
+type Client struct {
+	client *mongo.Client
+	hooks  *HookRegistry
+}
+
+// Use registers hook to run for every collection opened from this Client,
+// wrapping any call-site hook passed through options.*Options (Before* ahead
+// of it, After* after it).
+func (c *Client) Use(hook interface{}) {
+	c.hooks.Use(hook)
+}
+
+// UseFor registers hook to run only for collectionName.
+func (c *Client) UseFor(collectionName string, hook interface{}) {
+	c.hooks.UseFor(collectionName, hook)
+}
+
+// Close disconnects the underlying mongo.Client.
+func (c *Client) Close(ctx context.Context) error {
+	return c.client.Disconnect(ctx)
+}
+
+// Ping verifies the connection to the server is still alive.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx, nil)
+}
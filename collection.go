@@ -0,0 +1,412 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package qmgo
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/zhb127/qmgo/hook"
+	"github.com/zhb127/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection wraps the underlying mongo.Collection and is the type most
+// qmgo operations are called on.
+type Collection struct {
+	collection *mongo.Collection
+	name       string
+	hooks      *HookRegistry
+}
+
+// Use registers hook to run for every operation on this Collection, wrapping
+// any call-site hook passed through options.*Options (Before* ahead of it,
+// After* after it).
+func (c *Collection) Use(hook interface{}) {
+	c.hooks.UseFor(c.name, hook)
+}
+
+// runMaybeTransactional runs fn with ctx directly, or, when transactional is
+// true, with a context carrying a session started for it, committing only
+// if fn (which includes the After* hooks) returns nil.
+func (c *Collection) runMaybeTransactional(ctx context.Context, transactional bool, fn func(ctx context.Context) error) error {
+	if !transactional {
+		return fn(ctx)
+	}
+	return withTransaction(ctx, c.collection, fn)
+}
+
+// InsertOne inserts a single document into the collection.
+func (c *Collection) InsertOne(ctx context.Context, doc interface{}, opts ...options.InsertOneOptions) (result *InsertOneResult, err error) {
+	var h interface{}
+	var transactional bool
+	if len(opts) > 0 {
+		h = opts[0].InsertHook
+		transactional = opts[0].Transactional
+	}
+
+	err = c.runMaybeTransactional(ctx, transactional, func(opCtx context.Context) error {
+		if err := hook.DoChainBeforeInsertWithCallSite(opCtx, c.hooks.handlers(c.name), h, doc); err != nil {
+			return err
+		}
+
+		res, err := c.collection.InsertOne(opCtx, doc)
+		if err != nil {
+			return err
+		}
+		result = &InsertOneResult{InsertedID: res.InsertedID}
+
+		return hook.DoChainWithCallSite(opCtx, c.hooks.handlers(c.name), h, hook.AfterInsert)
+	})
+	return result, err
+}
+
+// InsertMany inserts multiple documents into the collection.
+func (c *Collection) InsertMany(ctx context.Context, docs interface{}, opts ...options.InsertManyOptions) (result *InsertManyResult, err error) {
+	var h interface{}
+	if len(opts) > 0 {
+		h = opts[0].InsertHook
+	}
+
+	sDocs, err := toSliceOfDocs(docs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, doc := range sDocs {
+		if err = hook.DoChainBeforeInsertWithCallSite(ctx, c.hooks.handlers(c.name), hook.CallSiteAt(h, i), doc); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := c.collection.InsertMany(ctx, sDocs)
+	if err != nil {
+		return nil, err
+	}
+	result = &InsertManyResult{InsertedIDs: res.InsertedIDs}
+
+	for i := range sDocs {
+		if err = hook.DoChainWithCallSite(ctx, c.hooks.handlers(c.name), hook.CallSiteAt(h, i), hook.AfterInsert); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// Find returns a *Query that can be used to fetch one or many documents
+// matching filter.
+func (c *Collection) Find(ctx context.Context, filter interface{}, opts ...options.FindOptions) *Query {
+	var h interface{}
+	if len(opts) > 0 {
+		h = opts[0].QueryHook
+	}
+
+	handlers := c.hooks.handlers(c.name)
+	decorators := handlers
+	if h != nil {
+		decorators = append(decorators, h)
+	}
+
+	decorated, err := hook.DecorateFilter(decorators, filter)
+	return &Query{
+		ctx:        ctx,
+		collection: c.collection,
+		filter:     decorated,
+		err:        err,
+		queryHook:  h,
+		registered: handlers,
+	}
+}
+
+// UpdateOne updates a single document matching filter.
+func (c *Collection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...options.UpdateOptions) (err error) {
+	var h interface{}
+	var transactional, loadOriginal bool
+	if len(opts) > 0 {
+		h = opts[0].UpdateHook
+		transactional = opts[0].Transactional
+		loadOriginal = opts[0].LoadOriginal
+	}
+
+	return c.runMaybeTransactional(ctx, transactional, func(opCtx context.Context) error {
+		change := &hook.UpdateChange{Filter: filter, Update: update, Multi: false}
+		if loadOriginal {
+			before, err := c.fetchRawDocs(opCtx, filter, false)
+			if err != nil {
+				return err
+			}
+			change.Before = before
+		}
+
+		if err := hook.DoChainBeforeUpdateWithCallSite(opCtx, c.hooks.handlers(c.name), h, change); err != nil {
+			return err
+		}
+
+		if _, err := c.collection.UpdateOne(opCtx, filter, update); err != nil {
+			return err
+		}
+
+		return hook.DoChainWithCallSite(opCtx, c.hooks.handlers(c.name), h, hook.AfterUpdate)
+	})
+}
+
+// UpdateId updates the document with the given id.
+func (c *Collection) UpdateId(ctx context.Context, id interface{}, update interface{}, opts ...options.UpdateOptions) (err error) {
+	return c.UpdateOne(ctx, bson.M{"_id": id}, update, opts...)
+}
+
+// UpdateAll updates every document matching filter.
+func (c *Collection) UpdateAll(ctx context.Context, filter interface{}, update interface{}, opts ...options.UpdateOptions) (result *UpdateResult, err error) {
+	var h interface{}
+	var transactional, loadOriginal bool
+	if len(opts) > 0 {
+		h = opts[0].UpdateHook
+		transactional = opts[0].Transactional
+		loadOriginal = opts[0].LoadOriginal
+	}
+
+	err = c.runMaybeTransactional(ctx, transactional, func(opCtx context.Context) error {
+		change := &hook.UpdateChange{Filter: filter, Update: update, Multi: true}
+		if loadOriginal {
+			before, err := c.fetchRawDocs(opCtx, filter, true)
+			if err != nil {
+				return err
+			}
+			change.Before = before
+		}
+
+		if err := hook.DoChainBeforeUpdateWithCallSite(opCtx, c.hooks.handlers(c.name), h, change); err != nil {
+			return err
+		}
+
+		res, err := c.collection.UpdateMany(opCtx, filter, update)
+		if err != nil {
+			return err
+		}
+		result = &UpdateResult{MatchedCount: res.MatchedCount, ModifiedCount: res.ModifiedCount}
+
+		return hook.DoChainWithCallSite(opCtx, c.hooks.handlers(c.name), h, hook.AfterUpdate)
+	})
+	return result, err
+}
+
+// ReplaceOne replaces a single document matching filter with doc.
+func (c *Collection) ReplaceOne(ctx context.Context, filter interface{}, doc interface{}, opts ...options.ReplaceOptions) (err error) {
+	var h interface{}
+	var transactional bool
+	if len(opts) > 0 {
+		h = opts[0].UpdateHook
+		transactional = opts[0].Transactional
+	}
+
+	return c.runMaybeTransactional(ctx, transactional, func(opCtx context.Context) error {
+		if err := hook.DoChainBeforeWriteWithCallSite(opCtx, c.hooks.handlers(c.name), h, doc, hook.BeforeUpdate); err != nil {
+			return err
+		}
+
+		if _, err := c.collection.ReplaceOne(opCtx, filter, doc); err != nil {
+			return err
+		}
+
+		return hook.DoChainWithCallSite(opCtx, c.hooks.handlers(c.name), h, hook.AfterUpdate)
+	})
+}
+
+// Remove deletes a single document matching filter.
+func (c *Collection) Remove(ctx context.Context, filter interface{}, opts ...options.RemoveOptions) (err error) {
+	var h interface{}
+	var transactional, loadOriginal bool
+	if len(opts) > 0 {
+		h = opts[0].RemoveHook
+		transactional = opts[0].Transactional
+		loadOriginal = opts[0].LoadOriginal
+	}
+
+	return c.runMaybeTransactional(ctx, transactional, func(opCtx context.Context) error {
+		if err := hook.DoChainWithCallSite(opCtx, c.hooks.handlers(c.name), h, hook.BeforeRemove); err != nil {
+			return err
+		}
+
+		var deleted []bson.Raw
+		if loadOriginal {
+			var err error
+			deleted, err = c.fetchRawDocs(opCtx, filter, false)
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := c.deleteOrRewrite(opCtx, h, filter, false); err != nil {
+			return err
+		}
+
+		return hook.DoChainAfterRemoveWithCallSite(opCtx, c.hooks.handlers(c.name), h, deleted)
+	})
+}
+
+// RemoveId deletes the document with the given id.
+func (c *Collection) RemoveId(ctx context.Context, id interface{}, opts ...options.RemoveOptions) (err error) {
+	return c.Remove(ctx, bson.M{"_id": id}, opts...)
+}
+
+// RemoveAll deletes every document matching filter.
+func (c *Collection) RemoveAll(ctx context.Context, filter interface{}, opts ...options.RemoveOptions) (result *DeleteResult, err error) {
+	var h interface{}
+	var transactional, loadOriginal bool
+	if len(opts) > 0 {
+		h = opts[0].RemoveHook
+		transactional = opts[0].Transactional
+		loadOriginal = opts[0].LoadOriginal
+	}
+
+	err = c.runMaybeTransactional(ctx, transactional, func(opCtx context.Context) error {
+		if err := hook.DoChainWithCallSite(opCtx, c.hooks.handlers(c.name), h, hook.BeforeRemove); err != nil {
+			return err
+		}
+
+		var deleted []bson.Raw
+		if loadOriginal {
+			var err error
+			deleted, err = c.fetchRawDocs(opCtx, filter, true)
+			if err != nil {
+				return err
+			}
+		}
+
+		count, err := c.deleteOrRewrite(opCtx, h, filter, true)
+		if err != nil {
+			return err
+		}
+		result = &DeleteResult{DeletedCount: count}
+
+		return hook.DoChainAfterRemoveWithCallSite(opCtx, c.hooks.handlers(c.name), h, deleted)
+	})
+	return result, err
+}
+
+// Upsert inserts doc if no document matches filter, otherwise updates it.
+func (c *Collection) Upsert(ctx context.Context, filter interface{}, doc interface{}, opts ...options.UpsertOptions) (result *UpsertResult, err error) {
+	var h interface{}
+	var transactional bool
+	if len(opts) > 0 {
+		h = opts[0].UpsertHook
+		transactional = opts[0].Transactional
+	}
+
+	err = c.runMaybeTransactional(ctx, transactional, func(opCtx context.Context) error {
+		if err := hook.DoChainBeforeWriteWithCallSite(opCtx, c.hooks.handlers(c.name), h, doc, hook.BeforeUpsert); err != nil {
+			return err
+		}
+
+		res, err := c.collection.ReplaceOne(opCtx, filter, doc, mopts.Replace().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+		result = &UpsertResult{MatchedCount: res.MatchedCount, ModifiedCount: res.ModifiedCount, UpsertedCount: res.UpsertedCount, UpsertedID: res.UpsertedID}
+
+		return hook.DoChainWithCallSite(opCtx, c.hooks.handlers(c.name), h, hook.AfterUpsert)
+	})
+	return result, err
+}
+
+// UpsertId inserts doc with the given id if it doesn't exist, otherwise
+// updates it.
+func (c *Collection) UpsertId(ctx context.Context, id interface{}, doc interface{}, opts ...options.UpsertOptions) (result *UpsertResult, err error) {
+	return c.Upsert(ctx, bson.M{"_id": id}, doc, opts...)
+}
+
+// DropCollection drops the whole collection.
+func (c *Collection) DropCollection(ctx context.Context) error {
+	return c.collection.Drop(ctx)
+}
+
+// deleteOrRewrite deletes the document(s) matching filter, unless a
+// registered or call-site hook implements hook.RemoveRewriter, in which case
+// it applies that hook's update instead (e.g. qmgo/hook/builtin's
+// SoftDelete), returning the number of documents affected either way.
+func (c *Collection) deleteOrRewrite(ctx context.Context, callSiteHook interface{}, filter interface{}, multi bool) (int64, error) {
+	handlers := c.hooks.handlers(c.name)
+	if callSiteHook != nil {
+		handlers = append(handlers, callSiteHook)
+	}
+
+	if update, ok := hook.FindRemoveRewriter(handlers, filter); ok {
+		if multi {
+			res, err := c.collection.UpdateMany(ctx, filter, update)
+			if err != nil {
+				return 0, err
+			}
+			return res.ModifiedCount, nil
+		}
+		res, err := c.collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return 0, err
+		}
+		return res.ModifiedCount, nil
+	}
+
+	if multi {
+		res, err := c.collection.DeleteMany(ctx, filter)
+		if err != nil {
+			return 0, err
+		}
+		return res.DeletedCount, nil
+	}
+	res, err := c.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// fetchRawDocs fetches the document(s) matching filter as raw bson, for the
+// LoadOriginal pre-image options. multi fetches every match; otherwise only
+// the first.
+func (c *Collection) fetchRawDocs(ctx context.Context, filter interface{}, multi bool) ([]bson.Raw, error) {
+	if !multi {
+		var doc bson.Raw
+		if err := c.collection.FindOne(ctx, filter).Decode(&doc); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return []bson.Raw{doc}, nil
+	}
+
+	cursor, err := c.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []bson.Raw
+	for cursor.Next(ctx) {
+		docs = append(docs, append(bson.Raw{}, cursor.Current...))
+	}
+	return docs, cursor.Err()
+}
+
+// toSliceOfDocs normalizes the interface{} passed to InsertMany into a
+// []interface{} the driver accepts.
+func toSliceOfDocs(docs interface{}) ([]interface{}, error) {
+	sv := reflect.ValueOf(docs)
+	out := make([]interface{}, sv.Len())
+	for i := 0; i < sv.Len(); i++ {
+		out[i] = sv.Index(i).Interface()
+	}
+	return out, nil
+}
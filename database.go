@@ -0,0 +1,52 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package qmgo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Database wraps the underlying mongo.Database.
+type Database struct {
+	database *mongo.Database
+	hooks    *HookRegistry
+}
+
+// Use registers hook to run for every collection opened from this Database,
+// wrapping any call-site hook passed through options.*Options (Before* ahead
+// of it, After* after it).
+func (d *Database) Use(hook interface{}) {
+	d.hooks.Use(hook)
+}
+
+// UseFor registers hook to run only for collectionName.
+func (d *Database) UseFor(collectionName string, hook interface{}) {
+	d.hooks.UseFor(collectionName, hook)
+}
+
+// Collection gets a *Collection from the database by name.
+func (d *Database) Collection(name string) *Collection {
+	return &Collection{
+		collection: d.database.Collection(name),
+		name:       name,
+		hooks:      d.hooks,
+	}
+}
+
+// DropDatabase drops the whole database.
+func (d *Database) DropDatabase(ctx context.Context) error {
+	return d.database.Drop(ctx)
+}
@@ -0,0 +1,57 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package builtin
+
+import (
+	"fmt"
+
+	"github.com/zhb127/qmgo/operator"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type softDelete struct {
+	field string
+}
+
+// SoftDelete returns a hook that turns Remove/RemoveAll into setting field
+// to true instead of deleting the document, and excludes documents with
+// field set to true from Find, so soft-deleted documents behave as gone
+// without actually leaving the collection. Find filters must be bson.M;
+// see DecorateFilter.
+func SoftDelete(field string) *softDelete {
+	return &softDelete{field: field}
+}
+
+func (s *softDelete) RewriteRemove(filter interface{}) (interface{}, bool) {
+	return bson.M{operator.Set: bson.M{s.field: true}}, true
+}
+
+// DecorateFilter only supports bson.M filters, since it needs to inspect and
+// add a condition to the filter; passing any other filter shape (bson.D, a
+// struct, etc.) returns an error instead of silently leaving soft-deleted
+// documents visible to Find.
+func (s *softDelete) DecorateFilter(filter interface{}) (interface{}, error) {
+	m, ok := filter.(bson.M)
+	if !ok {
+		return nil, fmt.Errorf("qmgo: builtin.SoftDelete requires a bson.M filter, got %T", filter)
+	}
+	if _, exists := m[s.field]; exists {
+		return filter, nil
+	}
+	out := bson.M{s.field: bson.M{operator.Ne: true}}
+	for k, v := range m {
+		out[k] = v
+	}
+	return out, nil
+}
@@ -0,0 +1,147 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package builtin collects ready-made hooks driven by struct tags on the
+// document, so callers don't need to hand-write a hook for common
+// cross-cutting concerns like timestamps, validation, and soft-delete.
+package builtin
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zhb127/qmgo/hook"
+	"github.com/zhb127/qmgo/operator"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	tagCreatedAt = "createdAt"
+	tagUpdatedAt = "updatedAt"
+)
+
+// timestamps stamps createdAt/updatedAt fields on insert. BeforeUpdateWithChange
+// needs to write to the document's real BSON field name (its `bson:"..."` tag),
+// not the `qmgo:"..."` marker, so it learns that mapping from whatever document
+// type passes through BeforeInsertWithDoc and reuses it on update.
+type timestamps struct {
+	mu         sync.Mutex
+	bsonFields map[string]string // qmgo tag -> bson field name
+}
+
+// Timestamps returns a hook that stamps fields tagged `qmgo:"createdAt"`
+// and `qmgo:"updatedAt"` with the current time on insert, and refreshes the
+// `qmgo:"updatedAt"` field on update. Tagged fields must be of type
+// time.Time; on insert, a tagged field already set (non-zero) is left alone.
+func Timestamps() *timestamps {
+	return &timestamps{}
+}
+
+func (t *timestamps) BeforeInsertWithDoc(doc interface{}) error {
+	t.learnBsonFields(doc)
+	now := time.Now()
+	return setTaggedTimeFields(doc, map[string]time.Time{tagCreatedAt: now, tagUpdatedAt: now})
+}
+
+func (t *timestamps) BeforeUpdateWithChange(change *hook.UpdateChange) error {
+	set, ok := change.Update.(bson.M)
+	if !ok {
+		return nil
+	}
+	sub, ok := set[operator.Set].(bson.M)
+	if !ok {
+		sub = bson.M{}
+		set[operator.Set] = sub
+	}
+	sub[t.bsonField(tagUpdatedAt)] = time.Now()
+	return nil
+}
+
+// learnBsonFields records, for each qmgo-tagged field on doc, the
+// bson field name mongo actually stores it under, so BeforeUpdateWithChange
+// can target the same field later.
+func (t *timestamps) learnBsonFields(doc interface{}) {
+	v := reflect.ValueOf(doc)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	st := v.Elem().Type()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.bsonFields == nil {
+		t.bsonFields = map[string]string{}
+	}
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		tag := f.Tag.Get("qmgo")
+		if tag == "" {
+			continue
+		}
+		t.bsonFields[tag] = bsonFieldName(f)
+	}
+}
+
+// bsonField returns the bson field name learned for qmgo tag, falling back
+// to the tag text itself if no document has been seen yet.
+func (t *timestamps) bsonField(tag string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if name, ok := t.bsonFields[tag]; ok {
+		return name
+	}
+	return tag
+}
+
+// bsonFieldName derives the name the mongo driver stores f under: the first
+// comma-separated segment of its `bson:"..."` tag, or its lowercased Go name
+// if the tag is absent/empty, matching the driver's own default behavior.
+func bsonFieldName(f reflect.StructField) string {
+	tag := f.Tag.Get("bson")
+	if tag == "" {
+		return strings.ToLower(f.Name)
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+	return name
+}
+
+// setTaggedTimeFields walks doc's fields looking for a `qmgo:"..."` tag
+// matching one of values, setting that field to the paired time if it's
+// still zero. doc must be a pointer to a struct; anything else is ignored.
+func setTaggedTimeFields(doc interface{}, values map[string]time.Time) error {
+	v := reflect.ValueOf(doc)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v = v.Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		now, wanted := values[t.Field(i).Tag.Get("qmgo")]
+		if !wanted {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Type() != reflect.TypeOf(time.Time{}) || !fv.CanSet() {
+			continue
+		}
+		if fv.Interface().(time.Time).IsZero() {
+			fv.Set(reflect.ValueOf(now))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package builtin
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type validateHook struct {
+	v *validator.Validate
+}
+
+// Validate returns a hook that runs go-playground/validator's `validate:"..."`
+// struct tags against the document on InsertOne/InsertMany and against the
+// replacement document on ReplaceOne/Upsert/UpsertId, failing the operation
+// if any tag doesn't hold. It does not run on UpdateOne/UpdateAll: those only
+// ever carry a bson.M filter/update, not a typed document, so there's
+// nothing to validate against the struct tags.
+func Validate() *validateHook {
+	return &validateHook{v: validator.New()}
+}
+
+func (h *validateHook) BeforeInsertWithDoc(doc interface{}) error {
+	return h.validate(doc)
+}
+
+func (h *validateHook) BeforeWriteWithDoc(doc interface{}) error {
+	return h.validate(doc)
+}
+
+func (h *validateHook) validate(doc interface{}) error {
+	if err := h.v.Struct(doc); err != nil {
+		return fmt.Errorf("qmgo: validation failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package hook
+
+import (
+	"context"
+	"errors"
+)
+
+// DoChain runs opType against each handler in handlers, in the order
+// middleware chains conventionally run: Before* ops fire front-to-back and
+// stop at the first error (the remaining handlers, including the matching
+// After* phase, are skipped by the caller). After* ops fire back-to-front
+// and always run every handler regardless of earlier errors, joining
+// whatever they return with errors.Join. Handlers implementing the ctx-aware
+// variant of opType (e.g. BeforeInsertCtx) run that instead, with ctx.
+func DoChain(ctx context.Context, handlers []interface{}, opType opType) error {
+	if isBeforeOp(opType) {
+		for _, h := range handlers {
+			if err := DoCtx(ctx, h, opType); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var errs []error
+	for i := len(handlers) - 1; i >= 0; i-- {
+		if err := DoCtx(ctx, handlers[i], opType); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DoChainWithCallSite is DoChain with callSite appended to the end of
+// handlers when non-nil, so a per-call hook passed through options.*Options
+// runs after the registered chain for Before* ops and before it for After*
+// ops.
+func DoChainWithCallSite(ctx context.Context, handlers []interface{}, callSite interface{}, opType opType) error {
+	if callSite != nil {
+		handlers = append(handlers, callSite)
+	}
+	return DoChain(ctx, handlers, opType)
+}
+
+func isBeforeOp(opType opType) bool {
+	switch opType {
+	case BeforeInsert, BeforeUpdate, BeforeQuery, BeforeRemove, BeforeUpsert:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package hook
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// UpdateChange carries what's changing to a BeforeUpdateWithChange hook:
+// the filter and update passed to the operation, whether it targets
+// multiple documents, and, when options.UpdateOptions.LoadOriginal is set,
+// the pre-image of the document(s) the filter matched before the update ran.
+type UpdateChange struct {
+	Filter interface{}
+	Update interface{}
+	Multi  bool
+	Before []bson.Raw
+}
+
+type beforeUpdateWithChangeHook interface {
+	BeforeUpdateWithChange(change *UpdateChange) error
+}
+
+type afterRemoveWithDocHook interface {
+	AfterRemoveWithDoc(docs []bson.Raw) error
+}
+
+// DoBeforeUpdate runs hook's before-update phase, preferring
+// BeforeUpdateWithChange(change) over plain BeforeUpdate/BeforeUpdateCtx when
+// hook implements it.
+func DoBeforeUpdate(ctx context.Context, h interface{}, change *UpdateChange) error {
+	if h == nil {
+		return nil
+	}
+	if hh, ok := h.(beforeUpdateWithChangeHook); ok {
+		return hh.BeforeUpdateWithChange(change)
+	}
+	return DoCtx(ctx, h, BeforeUpdate)
+}
+
+// DoAfterRemove runs hook's after-remove phase, preferring
+// AfterRemoveWithDoc(docs) over plain AfterRemove/AfterRemoveCtx when hook
+// implements it.
+func DoAfterRemove(ctx context.Context, h interface{}, docs []bson.Raw) error {
+	if h == nil {
+		return nil
+	}
+	if hh, ok := h.(afterRemoveWithDocHook); ok {
+		return hh.AfterRemoveWithDoc(docs)
+	}
+	return DoCtx(ctx, h, AfterRemove)
+}
+
+// DoChainBeforeUpdateWithCallSite runs DoBeforeUpdate across handlers in
+// registration order, stopping at the first error, with callSite appended
+// to the end when non-nil so it runs after the registered chain.
+func DoChainBeforeUpdateWithCallSite(ctx context.Context, handlers []interface{}, callSite interface{}, change *UpdateChange) error {
+	if callSite != nil {
+		handlers = append(handlers, callSite)
+	}
+	for _, h := range handlers {
+		if err := DoBeforeUpdate(ctx, h, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DoChainAfterRemoveWithCallSite runs DoAfterRemove across handlers in
+// reverse registration order, always running every handler and joining
+// whatever errors they return with errors.Join, with callSite appended to
+// the end of handlers (so it runs first) when non-nil.
+func DoChainAfterRemoveWithCallSite(ctx context.Context, handlers []interface{}, callSite interface{}, docs []bson.Raw) error {
+	if callSite != nil {
+		handlers = append(handlers, callSite)
+	}
+
+	var errs []error
+	for i := len(handlers) - 1; i >= 0; i-- {
+		if err := DoAfterRemove(ctx, handlers[i], docs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
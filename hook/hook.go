@@ -0,0 +1,234 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package hook defines the hook interfaces qmgo invokes around
+// insert/update/remove/upsert/query operations, plus the Do dispatcher
+// collection.go uses to invoke whichever of those interfaces a given
+// call-site hook value implements.
+package hook
+
+import "context"
+
+// opType identifies the point in an operation's lifecycle a hook runs at.
+type opType string
+
+const (
+	BeforeInsert opType = "BeforeInsert"
+	AfterInsert  opType = "AfterInsert"
+	BeforeUpdate opType = "BeforeUpdate"
+	AfterUpdate  opType = "AfterUpdate"
+	BeforeQuery  opType = "BeforeQuery"
+	AfterQuery   opType = "AfterQuery"
+	BeforeRemove opType = "BeforeRemove"
+	AfterRemove  opType = "AfterRemove"
+	BeforeUpsert opType = "BeforeUpsert"
+	AfterUpsert  opType = "AfterUpsert"
+)
+
+type InsertHook interface {
+	BeforeInsert() error
+	AfterInsert() error
+}
+
+type UpdateHook interface {
+	BeforeUpdate() error
+	AfterUpdate() error
+}
+
+type QueryHook interface {
+	BeforeQuery() error
+	AfterQuery() error
+}
+
+type RemoveHook interface {
+	BeforeRemove() error
+	AfterRemove() error
+}
+
+type UpsertHook interface {
+	BeforeUpsert() error
+	AfterUpsert() error
+}
+
+type beforeInsertHook interface {
+	BeforeInsert() error
+}
+type afterInsertHook interface {
+	AfterInsert() error
+}
+type beforeUpdateHook interface {
+	BeforeUpdate() error
+}
+type afterUpdateHook interface {
+	AfterUpdate() error
+}
+type beforeQueryHook interface {
+	BeforeQuery() error
+}
+type afterQueryHook interface {
+	AfterQuery() error
+}
+type beforeRemoveHook interface {
+	BeforeRemove() error
+}
+type afterRemoveHook interface {
+	AfterRemove() error
+}
+type beforeUpsertHook interface {
+	BeforeUpsert() error
+}
+type afterUpsertHook interface {
+	AfterUpsert() error
+}
+
+// Ctx-aware variants: a hook may implement these instead of (or in addition
+// to) the plain interfaces above to read/write within the session a
+// transactional operation is running in, surfaced via
+// qmgo.SessionFromContext(ctx).
+type beforeInsertCtxHook interface {
+	BeforeInsertCtx(ctx context.Context) error
+}
+type afterInsertCtxHook interface {
+	AfterInsertCtx(ctx context.Context) error
+}
+type beforeUpdateCtxHook interface {
+	BeforeUpdateCtx(ctx context.Context) error
+}
+type afterUpdateCtxHook interface {
+	AfterUpdateCtx(ctx context.Context) error
+}
+type beforeQueryCtxHook interface {
+	BeforeQueryCtx(ctx context.Context) error
+}
+type afterQueryCtxHook interface {
+	AfterQueryCtx(ctx context.Context) error
+}
+type beforeRemoveCtxHook interface {
+	BeforeRemoveCtx(ctx context.Context) error
+}
+type afterRemoveCtxHook interface {
+	AfterRemoveCtx(ctx context.Context) error
+}
+type beforeUpsertCtxHook interface {
+	BeforeUpsertCtx(ctx context.Context) error
+}
+type afterUpsertCtxHook interface {
+	AfterUpsertCtx(ctx context.Context) error
+}
+
+// Do invokes the op method of hook that matches opType, if hook implements
+// it. It's a no-op returning nil when hook doesn't implement the interface
+// for opType. It's equivalent to DoCtx(context.Background(), hook, opType)
+// except it never looks for the ctx-aware variant of opType.
+func Do(hook interface{}, opType opType) error {
+	if hook == nil {
+		return nil
+	}
+
+	switch opType {
+	case BeforeInsert:
+		if h, ok := hook.(beforeInsertHook); ok {
+			return h.BeforeInsert()
+		}
+	case AfterInsert:
+		if h, ok := hook.(afterInsertHook); ok {
+			return h.AfterInsert()
+		}
+	case BeforeUpdate:
+		if h, ok := hook.(beforeUpdateHook); ok {
+			return h.BeforeUpdate()
+		}
+	case AfterUpdate:
+		if h, ok := hook.(afterUpdateHook); ok {
+			return h.AfterUpdate()
+		}
+	case BeforeQuery:
+		if h, ok := hook.(beforeQueryHook); ok {
+			return h.BeforeQuery()
+		}
+	case AfterQuery:
+		if h, ok := hook.(afterQueryHook); ok {
+			return h.AfterQuery()
+		}
+	case BeforeRemove:
+		if h, ok := hook.(beforeRemoveHook); ok {
+			return h.BeforeRemove()
+		}
+	case AfterRemove:
+		if h, ok := hook.(afterRemoveHook); ok {
+			return h.AfterRemove()
+		}
+	case BeforeUpsert:
+		if h, ok := hook.(beforeUpsertHook); ok {
+			return h.BeforeUpsert()
+		}
+	case AfterUpsert:
+		if h, ok := hook.(afterUpsertHook); ok {
+			return h.AfterUpsert()
+		}
+	}
+	return nil
+}
+
+// DoCtx is Do, but first checks whether hook implements the ctx-aware
+// variant of opType (e.g. BeforeInsertCtx instead of BeforeInsert) and
+// prefers it when present.
+func DoCtx(ctx context.Context, hook interface{}, opType opType) error {
+	if hook == nil {
+		return nil
+	}
+
+	switch opType {
+	case BeforeInsert:
+		if h, ok := hook.(beforeInsertCtxHook); ok {
+			return h.BeforeInsertCtx(ctx)
+		}
+	case AfterInsert:
+		if h, ok := hook.(afterInsertCtxHook); ok {
+			return h.AfterInsertCtx(ctx)
+		}
+	case BeforeUpdate:
+		if h, ok := hook.(beforeUpdateCtxHook); ok {
+			return h.BeforeUpdateCtx(ctx)
+		}
+	case AfterUpdate:
+		if h, ok := hook.(afterUpdateCtxHook); ok {
+			return h.AfterUpdateCtx(ctx)
+		}
+	case BeforeQuery:
+		if h, ok := hook.(beforeQueryCtxHook); ok {
+			return h.BeforeQueryCtx(ctx)
+		}
+	case AfterQuery:
+		if h, ok := hook.(afterQueryCtxHook); ok {
+			return h.AfterQueryCtx(ctx)
+		}
+	case BeforeRemove:
+		if h, ok := hook.(beforeRemoveCtxHook); ok {
+			return h.BeforeRemoveCtx(ctx)
+		}
+	case AfterRemove:
+		if h, ok := hook.(afterRemoveCtxHook); ok {
+			return h.AfterRemoveCtx(ctx)
+		}
+	case BeforeUpsert:
+		if h, ok := hook.(beforeUpsertCtxHook); ok {
+			return h.BeforeUpsertCtx(ctx)
+		}
+	case AfterUpsert:
+		if h, ok := hook.(afterUpsertCtxHook); ok {
+			return h.AfterUpsertCtx(ctx)
+		}
+	}
+	return Do(hook, opType)
+}
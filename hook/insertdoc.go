@@ -0,0 +1,74 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package hook
+
+import (
+	"context"
+	"reflect"
+)
+
+// beforeInsertWithDocHook lets a hook inspect (and mutate, since doc is
+// normally a pointer) the document about to be inserted, for things like
+// qmgo/hook/builtin's struct-tag-driven timestamps and validation that a
+// plain, argument-less BeforeInsert can't do.
+type beforeInsertWithDocHook interface {
+	BeforeInsertWithDoc(doc interface{}) error
+}
+
+// DoBeforeInsert runs hook's before-insert phase, preferring
+// BeforeInsertWithDoc(doc) over plain BeforeInsert/BeforeInsertCtx when hook
+// implements it.
+func DoBeforeInsert(ctx context.Context, h interface{}, doc interface{}) error {
+	if h == nil {
+		return nil
+	}
+	if hh, ok := h.(beforeInsertWithDocHook); ok {
+		return hh.BeforeInsertWithDoc(doc)
+	}
+	return DoCtx(ctx, h, BeforeInsert)
+}
+
+// CallSiteAt returns callSite unchanged unless it is a slice, in which case
+// it returns element i (or nil if callSite is a shorter slice). This is the
+// convention InsertMany's InsertHook follows: either one hook shared across
+// every document, or a slice of per-document hooks parallel to the docs
+// being inserted.
+func CallSiteAt(callSite interface{}, i int) interface{} {
+	if callSite == nil {
+		return nil
+	}
+	v := reflect.ValueOf(callSite)
+	if v.Kind() != reflect.Slice {
+		return callSite
+	}
+	if i >= v.Len() {
+		return nil
+	}
+	return v.Index(i).Interface()
+}
+
+// DoChainBeforeInsertWithCallSite runs DoBeforeInsert across handlers in
+// registration order, stopping at the first error, with callSite appended
+// to the end when non-nil so it runs after the registered chain.
+func DoChainBeforeInsertWithCallSite(ctx context.Context, handlers []interface{}, callSite interface{}, doc interface{}) error {
+	if callSite != nil {
+		handlers = append(handlers, callSite)
+	}
+	for _, h := range handlers {
+		if err := DoBeforeInsert(ctx, h, doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
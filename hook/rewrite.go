@@ -0,0 +1,61 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package hook
+
+// RemoveRewriter lets a hook replace what Remove/RemoveAll does to a
+// matched document instead of deleting it, e.g. qmgo/hook/builtin's
+// SoftDelete turning a delete into a field update. RewriteRemove returns the
+// update document to apply in place of the delete, and ok=false to leave the
+// delete alone.
+type RemoveRewriter interface {
+	RewriteRemove(filter interface{}) (update interface{}, ok bool)
+}
+
+// FindRemoveRewriter returns the update from the first handler implementing
+// RemoveRewriter that opts to rewrite filter.
+func FindRemoveRewriter(handlers []interface{}, filter interface{}) (update interface{}, ok bool) {
+	for _, h := range handlers {
+		rw, isRewriter := h.(RemoveRewriter)
+		if !isRewriter {
+			continue
+		}
+		if update, ok := rw.RewriteRemove(filter); ok {
+			return update, true
+		}
+	}
+	return nil, false
+}
+
+// QueryFilterDecorator lets a hook rewrite a Find filter before it runs,
+// e.g. qmgo/hook/builtin's SoftDelete excluding soft-deleted documents. An
+// error return aborts the Find and is surfaced to the caller, for hooks
+// that can only decorate filter shapes they understand.
+type QueryFilterDecorator interface {
+	DecorateFilter(filter interface{}) (interface{}, error)
+}
+
+// DecorateFilter runs every handler implementing QueryFilterDecorator over
+// filter, in registration order, each seeing the previous one's result,
+// stopping at the first error.
+func DecorateFilter(handlers []interface{}, filter interface{}) (interface{}, error) {
+	for _, h := range handlers {
+		if d, ok := h.(QueryFilterDecorator); ok {
+			var err error
+			if filter, err = d.DecorateFilter(filter); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return filter, nil
+}
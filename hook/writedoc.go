@@ -0,0 +1,51 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package hook
+
+import "context"
+
+// beforeWriteWithDocHook lets a hook inspect the full replacement document
+// passed to ReplaceOne/Upsert/UpsertId, unlike UpdateOne/UpdateAll which only
+// ever carry a bson.M filter/update with no typed document to look at.
+type beforeWriteWithDocHook interface {
+	BeforeWriteWithDoc(doc interface{}) error
+}
+
+// DoBeforeWrite runs hook's before-write phase, preferring
+// BeforeWriteWithDoc(doc) over the plain fallback opType (BeforeUpdate for
+// ReplaceOne, BeforeUpsert for Upsert/UpsertId) when hook implements it.
+func DoBeforeWrite(ctx context.Context, h interface{}, doc interface{}, fallback opType) error {
+	if h == nil {
+		return nil
+	}
+	if hh, ok := h.(beforeWriteWithDocHook); ok {
+		return hh.BeforeWriteWithDoc(doc)
+	}
+	return DoCtx(ctx, h, fallback)
+}
+
+// DoChainBeforeWriteWithCallSite runs DoBeforeWrite across handlers in
+// registration order, stopping at the first error, with callSite appended
+// to the end when non-nil so it runs after the registered chain.
+func DoChainBeforeWriteWithCallSite(ctx context.Context, handlers []interface{}, callSite interface{}, doc interface{}, fallback opType) error {
+	if callSite != nil {
+		handlers = append(handlers, callSite)
+	}
+	for _, h := range handlers {
+		if err := DoBeforeWrite(ctx, h, doc, fallback); err != nil {
+			return err
+		}
+	}
+	return nil
+}
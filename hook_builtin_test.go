@@ -0,0 +1,120 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package qmgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zhb127/qmgo/hook/builtin"
+	"github.com/zhb127/qmgo/operator"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type TimestampedUser struct {
+	Name      string    `bson:"name" validate:"required"`
+	Age       int       `bson:"age" validate:"gte=0"`
+	DeletedAt bool      `bson:"deleted_at"`
+	CreatedAt time.Time `bson:"created_at" qmgo:"createdAt"`
+	UpdatedAt time.Time `bson:"updated_at" qmgo:"updatedAt"`
+}
+
+func TestBuiltinTimestamps(t *testing.T) {
+	ast := require.New(t)
+	cli := initClient("test")
+	ctx := context.Background()
+	defer cli.Close(ctx)
+	defer cli.DropCollection(ctx)
+
+	cli.Collection.Use(builtin.Timestamps())
+
+	doc := &TimestampedUser{Name: "Lucas", Age: 7}
+	_, err := cli.InsertOne(ctx, doc)
+	ast.NoError(err)
+	ast.False(doc.CreatedAt.IsZero())
+	ast.False(doc.UpdatedAt.IsZero())
+
+	time.Sleep(time.Millisecond)
+	err = cli.UpdateOne(ctx, bson.M{"name": "Lucas"}, bson.M{operator.Set: bson.M{"age": 27}})
+	ast.NoError(err)
+
+	var updated TimestampedUser
+	ast.NoError(cli.Find(ctx, bson.M{"name": "Lucas"}).One(&updated))
+	ast.Equal(27, updated.Age)
+	ast.True(updated.UpdatedAt.After(doc.CreatedAt))
+	// BSON datetimes keep millisecond precision only.
+	ast.True(updated.CreatedAt.Equal(doc.CreatedAt.Truncate(time.Millisecond)))
+}
+
+func TestBuiltinSoftDelete(t *testing.T) {
+	ast := require.New(t)
+	cli := initClient("test")
+	ctx := context.Background()
+	defer cli.Close(ctx)
+	defer cli.DropCollection(ctx)
+
+	cli.Collection.Use(builtin.SoftDelete("deleted_at"))
+
+	_, err := cli.InsertOne(ctx, &TimestampedUser{Name: "Lucas", Age: 7})
+	ast.NoError(err)
+
+	ast.NoError(cli.Remove(ctx, bson.M{"name": "Lucas"}))
+
+	var gone TimestampedUser
+	ast.Error(cli.Find(ctx, bson.M{"name": "Lucas"}).One(&gone))
+
+	// Count shares Find's decorated filter, so the soft-deleted document is
+	// excluded here too, same as One/All.
+	count, err := cli.Find(ctx, bson.M{}).Count()
+	ast.NoError(err)
+	ast.EqualValues(0, count)
+
+	// the document still physically exists in the collection; only the
+	// SoftDelete-decorated filter hides it.
+	raw, err := cli.collection.CountDocuments(ctx, bson.M{})
+	ast.NoError(err)
+	ast.EqualValues(1, raw)
+}
+
+func TestBuiltinSoftDeleteRejectsNonBsonMFilter(t *testing.T) {
+	ast := require.New(t)
+	cli := initClient("test")
+	ctx := context.Background()
+	defer cli.Close(ctx)
+	defer cli.DropCollection(ctx)
+
+	cli.Collection.Use(builtin.SoftDelete("deleted_at"))
+
+	var gone TimestampedUser
+	err := cli.Find(ctx, bson.D{{Key: "name", Value: "Lucas"}}).One(&gone)
+	ast.Error(err)
+}
+
+func TestBuiltinValidate(t *testing.T) {
+	ast := require.New(t)
+	cli := initClient("test")
+	ctx := context.Background()
+	defer cli.Close(ctx)
+	defer cli.DropCollection(ctx)
+
+	cli.Collection.Use(builtin.Validate())
+
+	_, err := cli.InsertOne(ctx, &TimestampedUser{Name: "", Age: 7})
+	ast.Error(err)
+
+	_, err = cli.InsertOne(ctx, &TimestampedUser{Name: "Lucas", Age: 7})
+	ast.NoError(err)
+}
@@ -0,0 +1,92 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package qmgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zhb127/qmgo/hook"
+	"github.com/zhb127/qmgo/operator"
+	"github.com/zhb127/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type auditUpdateHook struct {
+	change *hook.UpdateChange
+}
+
+func (h *auditUpdateHook) BeforeUpdateWithChange(change *hook.UpdateChange) error {
+	h.change = change
+	return nil
+}
+
+func TestBeforeUpdateWithChange(t *testing.T) {
+	ast := require.New(t)
+	cli := initClient("test")
+	ctx := context.Background()
+	defer cli.Close(ctx)
+	defer cli.DropCollection(ctx)
+
+	_, err := cli.InsertOne(ctx, &UserHook{Name: "Lucas", Age: 7})
+	ast.NoError(err)
+
+	auditHook := &auditUpdateHook{}
+	err = cli.UpdateOne(ctx, bson.M{"name": "Lucas"}, bson.M{operator.Set: bson.M{"age": 27}}, options.UpdateOptions{
+		UpdateHook:   auditHook,
+		LoadOriginal: true,
+	})
+	ast.NoError(err)
+
+	ast.NotNil(auditHook.change)
+	ast.False(auditHook.change.Multi)
+	ast.Len(auditHook.change.Before, 1)
+
+	var before UserHook
+	ast.NoError(bson.Unmarshal(auditHook.change.Before[0], &before))
+	ast.Equal(7, before.Age)
+}
+
+type outboxRemoveHook struct {
+	deleted []bson.Raw
+}
+
+func (h *outboxRemoveHook) AfterRemoveWithDoc(docs []bson.Raw) error {
+	h.deleted = docs
+	return nil
+}
+
+func TestAfterRemoveWithDoc(t *testing.T) {
+	ast := require.New(t)
+	cli := initClient("test")
+	ctx := context.Background()
+	defer cli.Close(ctx)
+	defer cli.DropCollection(ctx)
+
+	_, err := cli.InsertOne(ctx, &UserHook{Name: "Lucas", Age: 7})
+	ast.NoError(err)
+
+	outboxHook := &outboxRemoveHook{}
+	err = cli.Remove(ctx, bson.M{"name": "Lucas"}, options.RemoveOptions{
+		RemoveHook:   outboxHook,
+		LoadOriginal: true,
+	})
+	ast.NoError(err)
+
+	ast.Len(outboxHook.deleted, 1)
+	var deleted UserHook
+	ast.NoError(bson.Unmarshal(outboxHook.deleted[0], &deleted))
+	ast.Equal("Lucas", deleted.Name)
+}
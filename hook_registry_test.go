@@ -0,0 +1,127 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package qmgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zhb127/qmgo/options"
+)
+
+type orderedInsertHook struct {
+	id    string
+	order *[]string
+}
+
+func (h *orderedInsertHook) BeforeInsert() error {
+	*h.order = append(*h.order, "before:"+h.id)
+	return nil
+}
+
+func (h *orderedInsertHook) AfterInsert() error {
+	*h.order = append(*h.order, "after:"+h.id)
+	return nil
+}
+
+func TestHookRegistryOrder(t *testing.T) {
+	ast := require.New(t)
+	cli := initClient("test")
+	ctx := context.Background()
+	defer cli.Close(ctx)
+	defer cli.DropCollection(ctx)
+
+	var order []string
+	cli.Collection.Use(&orderedInsertHook{id: "first", order: &order})
+	cli.Collection.Use(&orderedInsertHook{id: "second", order: &order})
+	callSite := &orderedInsertHook{id: "callsite", order: &order}
+
+	_, err := cli.InsertOne(ctx, &UserHook{Name: "Lucas", Age: 7}, options.InsertOneOptions{
+		InsertHook: callSite,
+	})
+	ast.NoError(err)
+
+	ast.Equal([]string{
+		"before:first", "before:second", "before:callsite",
+		"after:callsite", "after:second", "after:first",
+	}, order)
+}
+
+type erroringAfterHook struct {
+	ran *bool
+}
+
+func (h *erroringAfterHook) AfterInsert() error {
+	*h.ran = true
+	return errors.New("registered after failed")
+}
+
+func TestHookRegistryAfterAlwaysRuns(t *testing.T) {
+	ast := require.New(t)
+	cli := initClient("test")
+	ctx := context.Background()
+	defer cli.Close(ctx)
+	defer cli.DropCollection(ctx)
+
+	ran := false
+	cli.Collection.Use(&erroringAfterHook{ran: &ran})
+
+	afterInsertCount = 0
+	u := &UserHook{Name: "Lucas", Age: 7}
+	_, err := cli.InsertOne(ctx, u, options.InsertOneOptions{
+		InsertHook: u,
+	})
+	ast.Error(err)
+	ast.True(ran)
+	ast.Equal(1, afterInsertCount)
+}
+
+func TestHookRegistryCollectionUseIsScoped(t *testing.T) {
+	ast := require.New(t)
+	cli := initClient("test")
+	ctx := context.Background()
+	defer cli.Close(ctx)
+	defer cli.DropCollection(ctx)
+
+	other := cli.Database.Collection("other")
+	defer other.collection.Drop(ctx)
+
+	var order []string
+	other.Use(&orderedInsertHook{id: "scoped", order: &order})
+
+	_, err := cli.InsertOne(ctx, &UserHook{Name: "Lucas", Age: 7})
+	ast.NoError(err)
+	ast.Empty(order)
+
+	_, err = other.InsertOne(ctx, &UserHook{Name: "Lucas", Age: 7})
+	ast.NoError(err)
+	ast.Equal([]string{"before:scoped", "after:scoped"}, order)
+}
+
+func TestHookRegistryUseFor(t *testing.T) {
+	ast := require.New(t)
+	cli := initClient("test")
+	ctx := context.Background()
+	defer cli.Close(ctx)
+	defer cli.DropCollection(ctx)
+
+	var order []string
+	cli.Client.UseFor("other", &orderedInsertHook{id: "scoped", order: &order})
+
+	_, err := cli.InsertOne(ctx, &UserHook{Name: "Lucas", Age: 7})
+	ast.NoError(err)
+	ast.Empty(order)
+}
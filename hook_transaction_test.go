@@ -0,0 +1,65 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package qmgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zhb127/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type txnInsertHook struct {
+	failAfter   bool
+	sawSession  bool
+	afterCalled bool
+}
+
+func (h *txnInsertHook) BeforeInsertCtx(ctx context.Context) error {
+	_, ok := SessionFromContext(ctx)
+	h.sawSession = ok
+	return nil
+}
+
+func (h *txnInsertHook) AfterInsertCtx(ctx context.Context) error {
+	h.afterCalled = true
+	if h.failAfter {
+		return errors.New("after insert failed")
+	}
+	return nil
+}
+
+func TestTransactionalInsertRollsBackOnHookError(t *testing.T) {
+	ast := require.New(t)
+	cli := initClient("test")
+	ctx := context.Background()
+	defer cli.Close(ctx)
+	defer cli.DropCollection(ctx)
+
+	h := &txnInsertHook{failAfter: true}
+	_, err := cli.InsertOne(ctx, &UserHook{Name: "Lucas", Age: 7}, options.InsertOneOptions{
+		InsertHook:    h,
+		Transactional: true,
+	})
+	ast.Error(err)
+	ast.True(h.sawSession)
+	ast.True(h.afterCalled)
+
+	count, err := cli.Find(ctx, bson.M{"name": "Lucas"}).Count()
+	ast.NoError(err)
+	ast.EqualValues(0, count, "the insert should have been rolled back")
+}
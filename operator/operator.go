@@ -0,0 +1,34 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package operator defines the mongo query/update operator name constants
+// used across qmgo, so callers don't need to hard-code raw strings like
+// "$set" when building bson.M filters and updates.
+package operator
+
+const (
+	Set         = "$set"
+	SetOnInsert = "$setOnInsert"
+	Unset       = "$unset"
+	Inc         = "$inc"
+	Push        = "$push"
+	Pull        = "$pull"
+	AddToSet    = "$addToSet"
+	Ne          = "$ne"
+	In          = "$in"
+	Nin         = "$nin"
+	Gt          = "$gt"
+	Gte         = "$gte"
+	Lt          = "$lt"
+	Lte         = "$lte"
+)
@@ -0,0 +1,81 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package options holds the per-call option structs accepted by Collection's
+// methods, layered on top of the native mongo-driver options.
+package options
+
+// InsertOneOptions holds the options for the InsertOne operation.
+type InsertOneOptions struct {
+	// InsertHook runs BeforeInsert/AfterInsert around the operation.
+	InsertHook interface{}
+	// Transactional runs the operation inside a session transaction that
+	// only commits if the After* hooks succeed, rolling back otherwise.
+	Transactional bool
+}
+
+// InsertManyOptions holds the options for the InsertMany operation.
+type InsertManyOptions struct {
+	// InsertHook runs BeforeInsert/AfterInsert around the operation. It may
+	// be a single hook shared by every document, or a slice of hooks
+	// parallel to docs, one per document.
+	InsertHook interface{}
+}
+
+// UpdateOptions holds the options for the UpdateOne/UpdateId/UpdateAll operations.
+type UpdateOptions struct {
+	// UpdateHook runs BeforeUpdate/AfterUpdate around the operation.
+	UpdateHook interface{}
+	// Transactional runs the operation inside a session transaction that
+	// only commits if the After* hooks succeed, rolling back otherwise.
+	Transactional bool
+	// LoadOriginal fetches the matched document(s) before the update runs
+	// and hands them to a BeforeUpdateWithChange hook as UpdateChange.Before.
+	LoadOriginal bool
+}
+
+// ReplaceOptions holds the options for the ReplaceOne operation.
+type ReplaceOptions struct {
+	// UpdateHook runs BeforeUpdate/AfterUpdate around the operation.
+	UpdateHook interface{}
+	// Transactional runs the operation inside a session transaction that
+	// only commits if the After* hooks succeed, rolling back otherwise.
+	Transactional bool
+}
+
+// RemoveOptions holds the options for the Remove/RemoveId/RemoveAll operations.
+type RemoveOptions struct {
+	// RemoveHook runs BeforeRemove/AfterRemove around the operation.
+	RemoveHook interface{}
+	// Transactional runs the operation inside a session transaction that
+	// only commits if the After* hooks succeed, rolling back otherwise.
+	Transactional bool
+	// LoadOriginal fetches the matched document(s) before the delete runs
+	// and hands them to an AfterRemoveWithDoc hook.
+	LoadOriginal bool
+}
+
+// UpsertOptions holds the options for the Upsert/UpsertId operations.
+type UpsertOptions struct {
+	// UpsertHook runs BeforeUpsert/AfterUpsert around the operation.
+	UpsertHook interface{}
+	// Transactional runs the operation inside a session transaction that
+	// only commits if the After* hooks succeed, rolling back otherwise.
+	Transactional bool
+}
+
+// FindOptions holds the options for the Find operation.
+type FindOptions struct {
+	// QueryHook runs BeforeQuery/AfterQuery around the operation.
+	QueryHook interface{}
+}
@@ -0,0 +1,60 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package qmgo is a lightweight MongoDB driver wrapper that is driven by
+// go.mongodb.org/mongo-driver and aims to be easy to use with chain calls
+// and a feel similar to mgo.
+package qmgo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Config holds the connection settings used by Open.
+type Config struct {
+	Uri              string
+	Database         string
+	Coll             string
+	ConnectTimeoutMS *int64
+}
+
+// QmgoClient combines Client, Database and Collection so callers can chain
+// straight into collection-level calls off the value Open returns.
+type QmgoClient struct {
+	*Client
+	*Database
+	*Collection
+}
+
+// Open connects to MongoDB per conf and returns a QmgoClient scoped to
+// conf.Database/conf.Coll.
+func Open(ctx context.Context, conf *Config) (cli *QmgoClient, err error) {
+	mCli, err := mongo.Connect(ctx, options.Client().ApplyURI(conf.Uri))
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := &HookRegistry{}
+	client := &Client{client: mCli, hooks: hooks}
+	database := &Database{database: mCli.Database(conf.Database), hooks: hooks}
+	collection := database.Collection(conf.Coll)
+
+	return &QmgoClient{
+		Client:     client,
+		Database:   database,
+		Collection: collection,
+	}, nil
+}
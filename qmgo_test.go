@@ -0,0 +1,31 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package qmgo
+
+import "context"
+
+// initClient opens a QmgoClient against a local test MongoDB instance,
+// scoped to the "qmgo_test" database and the given collection. Tests are
+// expected to defer cli.Close and cli.DropCollection.
+func initClient(collName string) *QmgoClient {
+	cli, err := Open(context.Background(), &Config{
+		Uri:      "mongodb://localhost:27017",
+		Database: "qmgo_test",
+		Coll:     collName,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return cli
+}
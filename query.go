@@ -0,0 +1,80 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package qmgo
+
+import (
+	"context"
+
+	"github.com/zhb127/qmgo/hook"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Query holds the state needed to run a Find against the collection, built
+// by Collection.Find and executed by One/All.
+type Query struct {
+	ctx        context.Context
+	collection *mongo.Collection
+	filter     interface{}
+	err        error
+	queryHook  interface{}
+	registered []interface{}
+}
+
+// One fetches the first document matching the query into result.
+func (q *Query) One(result interface{}) error {
+	if q.err != nil {
+		return q.err
+	}
+	if err := hook.DoChainWithCallSite(q.ctx, q.registered, q.queryHook, hook.BeforeQuery); err != nil {
+		return err
+	}
+
+	sr := q.collection.FindOne(q.ctx, q.filter)
+	if err := sr.Decode(result); err != nil {
+		return err
+	}
+
+	return hook.DoChainWithCallSite(q.ctx, q.registered, q.queryHook, hook.AfterQuery)
+}
+
+// All fetches every document matching the query into results, which must be
+// a pointer to a slice.
+func (q *Query) All(results interface{}) error {
+	if q.err != nil {
+		return q.err
+	}
+	if err := hook.DoChainWithCallSite(q.ctx, q.registered, q.queryHook, hook.BeforeQuery); err != nil {
+		return err
+	}
+
+	cursor, err := q.collection.Find(q.ctx, q.filter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(q.ctx)
+
+	if err := cursor.All(q.ctx, results); err != nil {
+		return err
+	}
+
+	return hook.DoChainWithCallSite(q.ctx, q.registered, q.queryHook, hook.AfterQuery)
+}
+
+// Count returns the number of documents matching the query.
+func (q *Query) Count() (int64, error) {
+	if q.err != nil {
+		return 0, q.err
+	}
+	return q.collection.CountDocuments(q.ctx, q.filter)
+}
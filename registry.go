@@ -0,0 +1,55 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package qmgo
+
+// HookRegistry collects hooks registered once via Use/UseFor instead of
+// being passed through options.*Options on every call. Registered hooks run
+// as a middleware-style chain: Before* handlers fire in registration order
+// and abort the operation on the first error, After* handlers fire in
+// reverse order and always all run. They wrap any call-site hook passed
+// through options.*Options: their Before* run ahead of its Before*, and
+// their After* run after its After*.
+type HookRegistry struct {
+	global []interface{}
+	byColl map[string][]interface{}
+}
+
+// Use registers hook to run for every collection reachable from the
+// Client/Database/Collection it was registered on.
+func (r *HookRegistry) Use(h interface{}) {
+	r.global = append(r.global, h)
+}
+
+// UseFor registers hook to run only for collectionName.
+func (r *HookRegistry) UseFor(collectionName string, h interface{}) {
+	if r.byColl == nil {
+		r.byColl = make(map[string][]interface{})
+	}
+	r.byColl[collectionName] = append(r.byColl[collectionName], h)
+}
+
+// handlers returns the hooks registered for collectionName, global hooks
+// first in registration order followed by the collection-specific ones.
+func (r *HookRegistry) handlers(collectionName string) []interface{} {
+	if r == nil {
+		return nil
+	}
+	if len(r.global) == 0 && len(r.byColl[collectionName]) == 0 {
+		return nil
+	}
+	out := make([]interface{}, 0, len(r.global)+len(r.byColl[collectionName]))
+	out = append(out, r.global...)
+	out = append(out, r.byColl[collectionName]...)
+	return out
+}
@@ -0,0 +1,45 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package qmgo
+
+// InsertOneResult is the result of an InsertOne operation.
+type InsertOneResult struct {
+	InsertedID interface{}
+}
+
+// InsertManyResult is the result of an InsertMany operation.
+type InsertManyResult struct {
+	InsertedIDs []interface{}
+}
+
+// UpdateResult is the result of an UpdateAll operation.
+type UpdateResult struct {
+	MatchedCount  int64
+	ModifiedCount int64
+	UpsertedCount int64
+	UpsertedID    interface{}
+}
+
+// DeleteResult is the result of a RemoveAll operation.
+type DeleteResult struct {
+	DeletedCount int64
+}
+
+// UpsertResult is the result of an Upsert/UpsertId operation.
+type UpsertResult struct {
+	MatchedCount  int64
+	ModifiedCount int64
+	UpsertedCount int64
+	UpsertedID    interface{}
+}
@@ -0,0 +1,60 @@
+/*
+ Copyright 2020 The Qmgo Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package qmgo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Session wraps the mongo.Session a transactional operation is running in.
+// It has no exported methods: SessionFromContext only reports whether a ctx
+// belongs to such an operation. The ctx handed to ctx-aware hooks carries
+// the driver session itself, so reads/writes a hook makes with that ctx run
+// within the same transaction.
+type Session struct {
+	session mongo.Session
+}
+
+type sessionCtxKey struct{}
+
+// SessionFromContext returns the Session a transactional operation (one run
+// with options.*Options.Transactional = true) started, if ctx is (or
+// descends from) that operation's context.
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	s, ok := ctx.Value(sessionCtxKey{}).(*Session)
+	return s, ok
+}
+
+// withTransaction runs fn inside a session transaction on collection's
+// client, committing if fn returns nil and aborting otherwise. fn is handed
+// a context carrying the Session, retrievable via SessionFromContext.
+func withTransaction(ctx context.Context, collection *mongo.Collection, fn func(ctx context.Context) error) error {
+	return collection.Database().Client().UseSession(ctx, func(sessCtx mongo.SessionContext) error {
+		if err := sessCtx.StartTransaction(); err != nil {
+			return err
+		}
+
+		fnCtx := context.WithValue(sessCtx, sessionCtxKey{}, &Session{session: sessCtx})
+		if err := fn(fnCtx); err != nil {
+			if abortErr := sessCtx.AbortTransaction(sessCtx); abortErr != nil {
+				return abortErr
+			}
+			return err
+		}
+
+		return sessCtx.CommitTransaction(sessCtx)
+	})
+}